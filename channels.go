@@ -0,0 +1,36 @@
+package imageunpacker
+
+import "fmt"
+
+// Channels is the number of float32 samples that make up one input pixel.
+type Channels int
+
+const (
+	ChannelsGray Channels = 1
+	ChannelsRGB  Channels = 3
+	ChannelsRGBA Channels = 4
+)
+
+func (c Channels) valid() bool {
+	switch c {
+	case ChannelsGray, ChannelsRGB, ChannelsRGBA:
+		return true
+	}
+	return false
+}
+
+// BitDepth selects the per-channel precision of the decoded image.
+type BitDepth int
+
+const (
+	BitDepth8  BitDepth = 8
+	BitDepth16 BitDepth = 16
+)
+
+func (b BitDepth) valid() bool {
+	return b == BitDepth8 || b == BitDepth16
+}
+
+func (b BitDepth) String() string {
+	return fmt.Sprintf("%d-bit", int(b))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/chai2010/webp"
+	"golang.org/x/image/bmp"
+)
+
+// encoderFunc writes im to w, honoring quality where the format supports it.
+type encoderFunc func(w io.Writer, im image.Image, quality int) error
+
+// encoderFor returns the encoderFunc for filename's extension, or an
+// encoderFunc that always reports an unsupported-format error.
+func encoderFor(filename string) encoderFunc {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".png":
+		return encodePNG
+	case ".jpg", ".jpeg":
+		return encodeJPEG
+	case ".bmp":
+		return encodeBMP
+	case ".webp":
+		return encodeWebP
+	default:
+		ext := path.Ext(filename)
+		return func(w io.Writer, im image.Image, quality int) error {
+			return fmt.Errorf("unpack: unsupported output format %q", ext)
+		}
+	}
+}
+
+func encodePNG(w io.Writer, im image.Image, quality int) error {
+	return png.Encode(w, im)
+}
+
+func encodeJPEG(w io.Writer, im image.Image, quality int) error {
+	return jpeg.Encode(w, im, &jpeg.Options{Quality: quality})
+}
+
+func encodeBMP(w io.Writer, im image.Image, quality int) error {
+	return bmp.Encode(w, im)
+}
+
+func encodeWebP(w io.Writer, im image.Image, quality int) error {
+	return webp.Encode(w, im, &webp.Options{Quality: float32(quality)})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func sampleImage() image.Image {
+	im := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			im.SetNRGBA(x, y, color.NRGBA{R: byte(x * 16), G: byte(y * 16), B: 128, A: 255})
+		}
+	}
+	return im
+}
+
+func TestEncoderForSupportedExtensions(t *testing.T) {
+	im := sampleImage()
+	cases := []string{"out.png", "out.jpg", "out.jpeg", "out.bmp", "out.webp", "OUT.PNG"}
+	for _, name := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encoderFor(name)(&buf, im, 90); err != nil {
+				t.Fatalf("encoderFor(%q): %v", name, err)
+			}
+			if buf.Len() == 0 {
+				t.Fatalf("encoderFor(%q): wrote no bytes", name)
+			}
+		})
+	}
+}
+
+func TestEncoderForUnsupportedExtension(t *testing.T) {
+	var buf bytes.Buffer
+	err := encoderFor("out.tiff")(&buf, sampleImage(), 90)
+	if err == nil {
+		t.Fatal("encoderFor(\"out.tiff\"): want error, got nil")
+	}
+	const want = `unpack: unsupported output format ".tiff"`
+	if err.Error() != want {
+		t.Errorf("err = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,115 @@
+// Command unpack converts a raw-float image file into a PNG, JPEG, BMP, or
+// WebP image, chosen by the extension of the -o flag.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"log"
+	"os"
+
+	"github.com/quells/go-image-unpacker"
+)
+
+func main() {
+	var input string
+	flag.StringVar(&input, "i", "", "Input data filepath")
+	var output string
+	flag.StringVar(&output, "o", "", "Output image filepath (.png, .jpg/.jpeg, .bmp, or .webp)")
+	var gamma float64
+	flag.Float64Var(&gamma, "gamma", 2.0, "Gamma correction exponent, ignored if -tonemap or -srgb is set (tonemapped output is left in [0, 1] instead)")
+	var quality int
+	flag.IntVar(&quality, "quality", 90, "JPEG/WebP quality (1-100)")
+	var tonemap string
+	flag.StringVar(&tonemap, "tonemap", "", "HDR tone-mapping mode: none, reinhard, reinhard-ext, aces")
+	var white float64
+	flag.Float64Var(&white, "white", 0, "White point for -tonemap reinhard-ext (0 means no white point, equivalent to plain reinhard)")
+	var exposure float64
+	flag.Float64Var(&exposure, "exposure", 0, "Exposure pre-scale in stops (EV), applied before tone-mapping")
+	var autoExposure bool
+	flag.BoolVar(&autoExposure, "auto-exposure", false, "Compute -exposure automatically from the 99th percentile of luminance")
+	var srgb bool
+	flag.BoolVar(&srgb, "srgb", false, "Apply the sRGB transfer function instead of -gamma")
+	var channels int
+	flag.IntVar(&channels, "channels", 0, "Override input channel count (1, 3, or 4); needed for legacy rfu1 files that aren't RGB")
+	var bits int
+	flag.IntVar(&bits, "bits", 0, "Override output bit depth (8 or 16)")
+	var parallelism int
+	flag.IntVar(&parallelism, "parallelism", 0, "Worker goroutines for row conversion (0 means GOMAXPROCS)")
+	var palette int
+	flag.IntVar(&palette, "palette", 0, "Quantize output to N colors (2-256) and write an indexed image; 0 disables")
+	var ditherFlag bool
+	flag.BoolVar(&ditherFlag, "dither", false, "Apply Floyd-Steinberg dithering when -palette is set")
+	flag.Parse()
+
+	if input == "" || output == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	tm, err := toneMapFor(tonemap, white)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := &imageunpacker.Options{
+		Gamma:        gamma,
+		ToneMap:      tm,
+		Exposure:     exposure,
+		AutoExposure: autoExposure,
+		SRGB:         srgb,
+		Channels:     imageunpacker.Channels(channels),
+		BitDepth:     imageunpacker.BitDepth(bits),
+		Parallelism:  parallelism,
+	}
+	if err := run(input, output, opts, quality, palette, ditherFlag); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func toneMapFor(name string, white float64) (imageunpacker.ToneMap, error) {
+	switch name {
+	case "", "none":
+		return nil, nil
+	case "reinhard":
+		return imageunpacker.ReinhardToneMap{}, nil
+	case "reinhard-ext":
+		return imageunpacker.ReinhardExtendedToneMap{White: white}, nil
+	case "aces":
+		return imageunpacker.ACESToneMap{}, nil
+	default:
+		return nil, fmt.Errorf("unpack: unknown -tonemap %q", name)
+	}
+}
+
+func run(input, output string, opts *imageunpacker.Options, quality, palette int, dither bool) error {
+	in, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	im, err := imageunpacker.Decode(in, opts)
+	if err != nil {
+		return err
+	}
+
+	if palette > 0 {
+		im, err = imageunpacker.Quantize(im, palette, dither)
+		if err != nil {
+			return err
+		}
+	}
+
+	return encode(output, im, quality)
+}
+
+func encode(filename string, im image.Image, quality int) error {
+	out, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return encoderFor(filename)(out, im, quality)
+}
@@ -0,0 +1,427 @@
+/*
+Package imageunpacker decodes images from a raw binary format commonly
+written by offline renderers:
+
+	[4]byte magic, uint16 width, uint16 height, [uint8 channels, uint8 bits],
+	floatN sample, ...
+
+The bracketed channels/bits fields are only present in the "rfu2" magic;
+the original "rfu1" format always implies 3 (RGB) float32 channels and an
+8-bit output depth. Samples are expected to be in the range [0, 1]. The
+package mirrors the shape of the decoders in image/png and
+golang.org/x/image/bmp: a DecodeConfig function for reading just the
+header, and a Decode function for reading a full image, both operating on
+an io.Reader so that callers are not required to buffer the whole file in
+memory. The format is also registered with image.RegisterFormat, so
+image.Decode recognizes it.
+*/
+package imageunpacker
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// MaxImageDimension is the largest width or height supported for decoded
+// images. This is not a technical limitation, just a sort of error-checking
+// in case something went wrong with the initial encoding.
+const MaxImageDimension = 8192 // pixels
+
+// MagicV1 is the signature of the original format: a bare width/height
+// header, always 3 float32 (RGB) channels decoded to 8-bit output.
+const MagicV1 = "rfu1"
+
+// MagicV2 is the signature of the format that adds explicit channel count
+// and output bit depth fields to the header.
+const MagicV2 = "rfu2"
+
+const magicSize = 4 // bytes
+const dimsSize = 4  // bytes, uint16 width + uint16 height
+const v2ExtraSize = 2
+const floatSize = 4 // bytes
+
+// Config holds the dimensions and sample layout of an image, as read from
+// its header.
+type Config struct {
+	Width    int
+	Height   int
+	Channels Channels
+	BitDepth BitDepth
+}
+
+// Options controls how a Decoder converts raw floats into pixels.
+type Options struct {
+	// Gamma is the exponent applied to each channel before clamping to
+	// the output range. A Gamma of 0 is treated as 1.0 (no correction).
+	// Ignored once ToneMap or SRGB is set.
+	Gamma float64
+
+	// ToneMap compresses HDR values (channels that exceed 1.0) into
+	// [0, 1] before Gamma/SRGB is applied. A nil ToneMap leaves values
+	// as-is, matching the pre-HDR behavior of this package.
+	ToneMap ToneMap
+
+	// Exposure pre-scales every channel by 2^Exposure before ToneMap
+	// runs.
+	Exposure float64
+
+	// AutoExposure computes Exposure automatically from the 99th
+	// percentile of per-pixel luminance, overriding any value set in
+	// Exposure. It requires the Decoder's reader to implement io.Seeker,
+	// since the image must be scanned once to find the percentile and
+	// again to decode it.
+	AutoExposure bool
+
+	// SRGB applies the sRGB OETF after ToneMap, instead of the Gamma
+	// power-law curve.
+	SRGB bool
+
+	// Channels and BitDepth override the values read from the file
+	// header. This exists mainly for MagicV1 files, whose header has no
+	// way to express anything but ChannelsRGB/BitDepth8.
+	Channels Channels
+	BitDepth BitDepth
+
+	// Parallelism is the number of goroutines used to convert and pack
+	// decoded rows. A value of 0 uses runtime.GOMAXPROCS(0); a value of 1
+	// forces single-threaded, deterministic decoding, which is useful in
+	// tests and benchmarks.
+	Parallelism int
+}
+
+func (o *Options) gamma() float64 {
+	if o == nil || o.Gamma == 0 {
+		return 1.0
+	}
+	return o.Gamma
+}
+
+// autoExposurePercentile is the luminance percentile used to pick an
+// automatic exposure value.
+const autoExposurePercentile = 99.0
+
+// A Decoder reads a raw-float image from an io.Reader one row at a time.
+type Decoder struct {
+	r      io.Reader
+	Config Config
+}
+
+// NewDecoder reads the header from r and returns a Decoder ready to decode
+// the pixel data that follows.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	magic := make([]byte, magicSize)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, fmt.Errorf("imageunpacker: reading header: %w", err)
+	}
+
+	var width, height int
+	var channels Channels
+	var bits BitDepth
+
+	switch string(magic) {
+	case MagicV1:
+		dims := make([]byte, dimsSize)
+		if _, err := io.ReadFull(r, dims); err != nil {
+			return nil, fmt.Errorf("imageunpacker: reading header: %w", err)
+		}
+		width, height = readDims(dims)
+		channels, bits = ChannelsRGB, BitDepth8
+
+	case MagicV2:
+		rest := make([]byte, dimsSize+v2ExtraSize)
+		if _, err := io.ReadFull(r, rest); err != nil {
+			return nil, fmt.Errorf("imageunpacker: reading header: %w", err)
+		}
+		width, height = readDims(rest[:dimsSize])
+		channels = Channels(rest[dimsSize])
+		bits = BitDepth(rest[dimsSize+1])
+		if !channels.valid() {
+			return nil, fmt.Errorf("imageunpacker: unsupported channel count %d", channels)
+		}
+		if !bits.valid() {
+			return nil, fmt.Errorf("imageunpacker: unsupported bit depth %d", bits)
+		}
+
+	default:
+		return nil, fmt.Errorf("imageunpacker: not a raw-float image (bad magic %q)", magic)
+	}
+
+	if width > MaxImageDimension || height > MaxImageDimension {
+		return nil, fmt.Errorf("imageunpacker: image too large, width: %d height: %d", width, height)
+	}
+
+	return &Decoder{
+		r: r,
+		Config: Config{
+			Width:    width,
+			Height:   height,
+			Channels: channels,
+			BitDepth: bits,
+		},
+	}, nil
+}
+
+func readDims(b []byte) (width, height int) {
+	width = int(b[0]) | int(b[1])<<8
+	height = int(b[2]) | int(b[3])<<8
+	return
+}
+
+// DecodeConfig reads the image header from r without decoding any pixel
+// data.
+func DecodeConfig(r io.Reader) (Config, error) {
+	d, err := NewDecoder(r)
+	if err != nil {
+		return Config{}, err
+	}
+	return d.Config, nil
+}
+
+// Decode reads a full raw-float image from r, applying opts along the way.
+// A nil opts is equivalent to &Options{Gamma: 1.0}. The concrete image
+// type depends on the file's channel count and bit depth (or opts'
+// overrides): *image.Gray or *image.Gray16 for single-channel input,
+// *image.NRGBA or *image.NRGBA64 otherwise.
+func Decode(r io.Reader, opts *Options) (image.Image, error) {
+	d, err := NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.Decode(opts)
+}
+
+// Decode streams the remaining pixel data from the Decoder's reader, one
+// row at a time, returning the assembled image.
+func (d *Decoder) Decode(opts *Options) (image.Image, error) {
+	width, height := d.Config.Width, d.Config.Height
+	if width == 0 || height == 0 {
+		return nil, errors.New("imageunpacker: zero-size image")
+	}
+
+	channels := d.Config.Channels
+	bits := d.Config.BitDepth
+	if opts != nil {
+		if opts.Channels != 0 {
+			channels = opts.Channels
+		}
+		if opts.BitDepth != 0 {
+			bits = opts.BitDepth
+		}
+	}
+	if !channels.valid() {
+		return nil, fmt.Errorf("imageunpacker: unsupported channel count %d", channels)
+	}
+	if !bits.valid() {
+		return nil, fmt.Errorf("imageunpacker: unsupported bit depth %d", bits)
+	}
+
+	gamma := opts.gamma()
+	ig := 1.0 / gamma
+	exposure := 0.0
+	var toneMap ToneMap
+	srgb := false
+	if opts != nil {
+		exposure = opts.Exposure
+		toneMap = opts.ToneMap
+		srgb = opts.SRGB
+		if opts.AutoExposure {
+			ev, err := d.autoExposure(channels)
+			if err != nil {
+				return nil, err
+			}
+			exposure = ev
+		}
+	}
+	exposureScale := math.Pow(2, exposure)
+
+	convert := func(f float64) float64 {
+		if exposureScale != 1.0 {
+			f *= exposureScale
+		}
+		if toneMap != nil {
+			f = toneMap.Map(f)
+		}
+		if srgb {
+			f = srgbOETF(f)
+		} else if toneMap == nil && gamma != 1.0 {
+			f = math.Pow(f, ig)
+		}
+		return f
+	}
+
+	elemSize := floatSize * int(channels)
+	im, processRow := newRowProcessor(channels, bits, width, height, convert)
+
+	workers := 1
+	if opts != nil && opts.Parallelism != 0 {
+		workers = opts.Parallelism
+	} else {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	type rowJob struct {
+		y   int
+		row []byte
+	}
+	jobs := make(chan rowJob, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				processRow(j.y, j.row)
+			}
+		}()
+	}
+
+	var readErr error
+	for y := 0; y < height; y++ {
+		row := make([]byte, elemSize*width)
+		if _, err := io.ReadFull(d.r, row); err != nil {
+			readErr = fmt.Errorf("imageunpacker: reading row %d: %w", y, err)
+			break
+		}
+		jobs <- rowJob{y, row}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	return im, nil
+}
+
+// newRowProcessor allocates the destination image for the given channel
+// count and bit depth, and returns a function that converts and writes one
+// decoded row of floats directly into the image's Pix slice. Each row
+// writes to a disjoint region of Pix, so the returned function is safe to
+// call concurrently for different values of y.
+func newRowProcessor(channels Channels, bits BitDepth, width, height int, convert func(float64) float64) (image.Image, func(y int, row []byte)) {
+	elemSize := floatSize * int(channels)
+
+	switch {
+	case channels == ChannelsGray && bits == BitDepth8:
+		im := image.NewGray(image.Rect(0, 0, width, height))
+		return im, func(y int, row []byte) {
+			off := im.PixOffset(0, y)
+			for x := 0; x < width; x++ {
+				v := float64(float32From(row[floatSize*x:]))
+				im.Pix[off+x] = toByte(convert(v))
+			}
+		}
+
+	case channels == ChannelsGray && bits == BitDepth16:
+		im := image.NewGray16(image.Rect(0, 0, width, height))
+		return im, func(y int, row []byte) {
+			off := im.PixOffset(0, y)
+			for x := 0; x < width; x++ {
+				v := float64(float32From(row[floatSize*x:]))
+				putUint16(im.Pix[off+2*x:], toUint16(convert(v)))
+			}
+		}
+
+	case bits == BitDepth8:
+		im := image.NewNRGBA(image.Rect(0, 0, width, height))
+		return im, func(y int, row []byte) {
+			off := im.PixOffset(0, y)
+			for x := 0; x < width; x++ {
+				base := elemSize * x
+				px := off + 4*x
+				im.Pix[px] = toByte(convert(float64(float32From(row[base:]))))
+				im.Pix[px+1] = toByte(convert(float64(float32From(row[base+floatSize:]))))
+				im.Pix[px+2] = toByte(convert(float64(float32From(row[base+2*floatSize:]))))
+				if channels == ChannelsRGBA {
+					im.Pix[px+3] = toByte(float64(float32From(row[base+3*floatSize:])))
+				} else {
+					im.Pix[px+3] = 255
+				}
+			}
+		}
+
+	default: // bits == BitDepth16, channels == ChannelsRGB or ChannelsRGBA
+		im := image.NewNRGBA64(image.Rect(0, 0, width, height))
+		return im, func(y int, row []byte) {
+			off := im.PixOffset(0, y)
+			for x := 0; x < width; x++ {
+				base := elemSize * x
+				px := off + 8*x
+				putUint16(im.Pix[px:], toUint16(convert(float64(float32From(row[base:])))))
+				putUint16(im.Pix[px+2:], toUint16(convert(float64(float32From(row[base+floatSize:])))))
+				putUint16(im.Pix[px+4:], toUint16(convert(float64(float32From(row[base+2*floatSize:])))))
+				if channels == ChannelsRGBA {
+					putUint16(im.Pix[px+6:], toUint16(float64(float32From(row[base+3*floatSize:]))))
+				} else {
+					putUint16(im.Pix[px+6:], 65535)
+				}
+			}
+		}
+	}
+}
+
+// autoExposure scans the image once to find the 99th-percentile luminance,
+// then returns the exposure value (in EV) that maps it to 1.0. It requires
+// the Decoder's reader to support seeking back to the start of the pixel
+// data once the scan completes.
+func (d *Decoder) autoExposure(channels Channels) (float64, error) {
+	seeker, ok := d.r.(io.Seeker)
+	if !ok {
+		return 0, errors.New("imageunpacker: AutoExposure requires a seekable reader")
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("imageunpacker: AutoExposure: %w", err)
+	}
+
+	width, height := d.Config.Width, d.Config.Height
+	elemSize := floatSize * int(channels)
+	luminances := make([]float64, 0, width*height)
+	rowBytes := make([]byte, elemSize*width)
+	for y := 0; y < height; y++ {
+		if _, err := io.ReadFull(d.r, rowBytes); err != nil {
+			return 0, fmt.Errorf("imageunpacker: AutoExposure: reading row %d: %w", y, err)
+		}
+		for x := 0; x < width; x++ {
+			off := elemSize * x
+			r := float64(float32From(rowBytes[off:]))
+			g, b := r, r
+			if channels != ChannelsGray {
+				g = float64(float32From(rowBytes[off+floatSize:]))
+				b = float64(float32From(rowBytes[off+2*floatSize:]))
+			}
+			luminances = append(luminances, luminance(r, g, b))
+		}
+	}
+
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("imageunpacker: AutoExposure: %w", err)
+	}
+
+	lum := percentile(luminances, autoExposurePercentile)
+	if lum <= 0 {
+		return 0, nil
+	}
+	return -math.Log2(lum), nil
+}
+
+func toByte(f float64) byte {
+	return byte(clamp(f*255.99, 0, 255))
+}
+
+func toUint16(f float64) uint16 {
+	return uint16(clamp(f*65535.0, 0, 65535))
+}
+
+func putUint16(b []byte, v uint16) {
+	b[0] = byte(v >> 8)
+	b[1] = byte(v)
+}
@@ -0,0 +1,46 @@
+package imageunpacker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// rawFloatImage builds an in-memory MagicV1 RGB image of size n x n, with a
+// fixed, repeatable pixel value so every benchmark does the same work.
+func rawFloatImage(n int) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteString(MagicV1)
+	binary.Write(buf, binary.LittleEndian, uint16(n))
+	binary.Write(buf, binary.LittleEndian, uint16(n))
+
+	px := make([]byte, elementSizeV1)
+	binary.LittleEndian.PutUint32(px[0:], math.Float32bits(0.2))
+	binary.LittleEndian.PutUint32(px[4:], math.Float32bits(0.5))
+	binary.LittleEndian.PutUint32(px[8:], math.Float32bits(0.8))
+	for i := 0; i < n*n; i++ {
+		buf.Write(px)
+	}
+	return buf.Bytes()
+}
+
+const elementSizeV1 = floatSize * 3
+
+func benchmarkUnpack(b *testing.B, n int) {
+	data := rawFloatImage(n)
+	opts := &Options{Gamma: 2.0}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(bytes.NewReader(data), opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnpack1k(b *testing.B) { benchmarkUnpack(b, 1024) }
+func BenchmarkUnpack4k(b *testing.B) { benchmarkUnpack(b, 4096) }
+func BenchmarkUnpack8k(b *testing.B) { benchmarkUnpack(b, 8192) }
@@ -0,0 +1,41 @@
+package imageunpacker
+
+import (
+	"image"
+	"image/color"
+	"io"
+)
+
+func init() {
+	image.RegisterFormat("rawfloat", MagicV1, decodeImage, decodeImageConfig)
+	image.RegisterFormat("rawfloat", MagicV2, decodeImage, decodeImageConfig)
+}
+
+func decodeImage(r io.Reader) (image.Image, error) {
+	return Decode(r, nil)
+}
+
+func decodeImageConfig(r io.Reader) (image.Config, error) {
+	c, err := DecodeConfig(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{
+		ColorModel: colorModel(c.Channels, c.BitDepth),
+		Width:      c.Width,
+		Height:     c.Height,
+	}, nil
+}
+
+func colorModel(channels Channels, bits BitDepth) color.Model {
+	switch {
+	case channels == ChannelsGray && bits == BitDepth16:
+		return color.Gray16Model
+	case channels == ChannelsGray:
+		return color.GrayModel
+	case bits == BitDepth16:
+		return color.NRGBA64Model
+	default:
+		return color.NRGBAModel
+	}
+}
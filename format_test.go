@@ -0,0 +1,63 @@
+package imageunpacker
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestImageRegisterFormatV1(t *testing.T) {
+	data := buildRawFloat(MagicV1, 4, 3, 0, 0, []float32{0.1, 0.2, 0.3})
+
+	im, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image.Decode: %v", err)
+	}
+	if format != "rawfloat" {
+		t.Errorf("format = %q, want %q", format, "rawfloat")
+	}
+	if b := im.Bounds(); b.Dx() != 4 || b.Dy() != 3 {
+		t.Errorf("bounds = %v, want 4x3", b)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image.DecodeConfig: %v", err)
+	}
+	if format != "rawfloat" {
+		t.Errorf("format = %q, want %q", format, "rawfloat")
+	}
+	if cfg.Width != 4 || cfg.Height != 3 {
+		t.Errorf("config = %+v, want 4x3", cfg)
+	}
+}
+
+func TestImageRegisterFormatV2(t *testing.T) {
+	data := buildRawFloat(MagicV2, 5, 6, int(ChannelsRGBA), int(BitDepth16), []float32{0.1, 0.2, 0.3, 0.4})
+
+	im, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image.Decode: %v", err)
+	}
+	if format != "rawfloat" {
+		t.Errorf("format = %q, want %q", format, "rawfloat")
+	}
+	if b := im.Bounds(); b.Dx() != 5 || b.Dy() != 6 {
+		t.Errorf("bounds = %v, want 5x6", b)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image.DecodeConfig: %v", err)
+	}
+	if format != "rawfloat" {
+		t.Errorf("format = %q, want %q", format, "rawfloat")
+	}
+	if cfg.Width != 5 || cfg.Height != 6 {
+		t.Errorf("config = %+v, want 5x6", cfg)
+	}
+	if cfg.ColorModel != color.NRGBA64Model {
+		t.Errorf("ColorModel = %v, want NRGBA64Model", cfg.ColorModel)
+	}
+}
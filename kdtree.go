@@ -0,0 +1,83 @@
+package imageunpacker
+
+import (
+	"image/color"
+	"sort"
+)
+
+// kdTree accelerates nearest-color lookups against a fixed palette.
+type kdTree struct {
+	root *kdNode
+}
+
+type kdNode struct {
+	color       rgb
+	index       byte
+	axis        int
+	left, right *kdNode
+}
+
+// newKDTree builds a balanced k-d tree over pal's colors.
+func newKDTree(pal color.Palette) *kdTree {
+	type entry struct {
+		c     rgb
+		index byte
+	}
+	entries := make([]entry, len(pal))
+	for i, c := range pal {
+		entries[i] = entry{rgbFromColor(c), byte(i)}
+	}
+
+	var build func(es []entry, depth int) *kdNode
+	build = func(es []entry, depth int) *kdNode {
+		if len(es) == 0 {
+			return nil
+		}
+		axis := depth % 3
+		sort.Slice(es, func(i, j int) bool {
+			return es[i].c.channel(axis) < es[j].c.channel(axis)
+		})
+		mid := len(es) / 2
+		return &kdNode{
+			color: es[mid].c,
+			index: es[mid].index,
+			axis:  axis,
+			left:  build(es[:mid], depth+1),
+			right: build(es[mid+1:], depth+1),
+		}
+	}
+
+	return &kdTree{root: build(entries, 0)}
+}
+
+// nearest returns the palette index of the color closest to c.
+func (t *kdTree) nearest(c rgb) byte {
+	best := t.root
+	bestDist := sqDist(c, t.root.color)
+	var search func(n *kdNode)
+	search = func(n *kdNode) {
+		if n == nil {
+			return
+		}
+		if d := sqDist(c, n.color); d < bestDist {
+			bestDist, best = d, n
+		}
+
+		diff := c.channel(n.axis) - n.color.channel(n.axis)
+		near, far := n.left, n.right
+		if diff > 0 {
+			near, far = n.right, n.left
+		}
+		search(near)
+		if diff*diff < bestDist {
+			search(far)
+		}
+	}
+	search(t.root)
+	return best.index
+}
+
+func sqDist(a, b rgb) float64 {
+	dr, dg, db := a.r-b.r, a.g-b.g, a.b-b.b
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,195 @@
+package imageunpacker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// Quantize reduces im to a palette of at most n colors (2-256), chosen by
+// median-cut quantization, and returns the result as an *image.Paletted
+// (which image/png encodes as a much smaller indexed PNG). If dither is
+// true, quantization error is distributed to neighboring pixels using
+// Floyd-Steinberg diffusion instead of simple nearest-color rounding.
+func Quantize(im image.Image, n int, dither bool) (*image.Paletted, error) {
+	if n < 2 || n > 256 {
+		return nil, fmt.Errorf("imageunpacker: palette size must be between 2 and 256, got %d", n)
+	}
+
+	bounds := im.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	samples := make([]rgb, 0, width*height)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			samples = append(samples, rgbAt(im, x, y))
+		}
+	}
+
+	palette := medianCut(samples, n)
+	tree := newKDTree(palette)
+
+	pi := image.NewPaletted(bounds, palette)
+	if !dither {
+		i := 0
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				pi.SetColorIndex(x, y, tree.nearest(samples[i]))
+				i++
+			}
+		}
+		return pi, nil
+	}
+
+	// Floyd-Steinberg: work on a mutable copy of the samples so diffused
+	// error from earlier pixels affects later ones in the same pass.
+	work := make([]rgb, len(samples))
+	copy(work, samples)
+	at := func(x, y int) int { return (y-bounds.Min.Y)*width + (x - bounds.Min.X) }
+	addErr := func(x, y int, err rgb, weight float64) {
+		if x < bounds.Min.X || x >= bounds.Max.X || y < bounds.Min.Y || y >= bounds.Max.Y {
+			return
+		}
+		i := at(x, y)
+		work[i].r += err.r * weight
+		work[i].g += err.g * weight
+		work[i].b += err.b * weight
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			i := at(x, y)
+			old := work[i]
+			idx := tree.nearest(old)
+			pi.SetColorIndex(x, y, idx)
+			quantized := rgbFromColor(palette[idx])
+			errR, errG, errB := old.r-quantized.r, old.g-quantized.g, old.b-quantized.b
+			diffused := rgb{errR, errG, errB}
+			addErr(x+1, y, diffused, 7.0/16)
+			addErr(x-1, y+1, diffused, 3.0/16)
+			addErr(x, y+1, diffused, 5.0/16)
+			addErr(x+1, y+1, diffused, 1.0/16)
+		}
+	}
+	return pi, nil
+}
+
+// rgb holds a color as float64 channels in [0, 255], wide enough to carry
+// diffused quantization error during dithering.
+type rgb struct {
+	r, g, b float64
+}
+
+func rgbAt(im image.Image, x, y int) rgb {
+	r, g, b, _ := im.At(x, y).RGBA()
+	return rgb{float64(r >> 8), float64(g >> 8), float64(b >> 8)}
+}
+
+func rgbFromColor(c color.Color) rgb {
+	r, g, b, _ := c.RGBA()
+	return rgb{float64(r >> 8), float64(g >> 8), float64(b >> 8)}
+}
+
+func (c rgb) channel(axis int) float64 {
+	switch axis {
+	case 0:
+		return c.r
+	case 1:
+		return c.g
+	default:
+		return c.b
+	}
+}
+
+// medianCut recursively splits the color box with the largest range along
+// its longest axis, until n buckets exist, then takes each bucket's mean
+// as a palette entry.
+func medianCut(samples []rgb, n int) color.Palette {
+	boxes := [][]rgb{samples}
+	for len(boxes) < n {
+		splitIdx, axis, ok := widestBox(boxes)
+		if !ok {
+			break
+		}
+		lo, hi := splitBox(boxes[splitIdx], axis)
+		boxes[splitIdx] = lo
+		boxes = append(boxes, hi)
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for i, box := range boxes {
+		palette[i] = meanColor(box)
+	}
+	return palette
+}
+
+// widestBox finds the box with the greatest range along its longest axis,
+// considering only boxes with at least two samples to split.
+func widestBox(boxes [][]rgb) (idx, axis int, ok bool) {
+	bestRange := -1.0
+	for i, box := range boxes {
+		if len(box) < 2 {
+			continue
+		}
+		a, r := longestAxis(box)
+		if r > bestRange {
+			bestRange, idx, axis, ok = r, i, a, true
+		}
+	}
+	return idx, axis, ok
+}
+
+func longestAxis(box []rgb) (axis int, axisRange float64) {
+	var lo, hi [3]float64
+	lo = [3]float64{box[0].r, box[0].g, box[0].b}
+	hi = lo
+	for _, c := range box[1:] {
+		v := [3]float64{c.r, c.g, c.b}
+		for a := 0; a < 3; a++ {
+			if v[a] < lo[a] {
+				lo[a] = v[a]
+			}
+			if v[a] > hi[a] {
+				hi[a] = v[a]
+			}
+		}
+	}
+	for a := 0; a < 3; a++ {
+		r := hi[a] - lo[a]
+		if r > axisRange {
+			axisRange, axis = r, a
+		}
+	}
+	return
+}
+
+// splitBox sorts box by axis and splits it at the median into two halves.
+func splitBox(box []rgb, axis int) (lo, hi []rgb) {
+	sorted := append([]rgb(nil), box...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].channel(axis) < sorted[j].channel(axis)
+	})
+	mid := len(sorted) / 2
+	return sorted[:mid], sorted[mid:]
+}
+
+func meanColor(box []rgb) color.NRGBA {
+	var sum rgb
+	for _, c := range box {
+		sum.r += c.r
+		sum.g += c.g
+		sum.b += c.b
+	}
+	n := float64(len(box))
+	return color.NRGBA{
+		R: clampByte(sum.r / n),
+		G: clampByte(sum.g / n),
+		B: clampByte(sum.b / n),
+		A: 255,
+	}
+}
+
+func clampByte(f float64) byte {
+	return byte(clamp(f, 0, 255))
+}
@@ -0,0 +1,122 @@
+package imageunpacker
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func randomNRGBA(w, h int, seed int64) *image.NRGBA {
+	r := rand.New(rand.NewSource(seed))
+	im := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			im.SetNRGBA(x, y, color.NRGBA{
+				R: byte(r.Intn(256)),
+				G: byte(r.Intn(256)),
+				B: byte(r.Intn(256)),
+				A: 255,
+			})
+		}
+	}
+	return im
+}
+
+func countUniqueColors(im image.Image) int {
+	seen := map[color.RGBA64]bool{}
+	b := im.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := im.At(x, y).RGBA()
+			seen[color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(bl), A: uint16(a)}] = true
+		}
+	}
+	return len(seen)
+}
+
+func TestQuantizeReducesColorCount(t *testing.T) {
+	im := randomNRGBA(32, 32, 1)
+	if got := countUniqueColors(im); got < 256 {
+		t.Fatalf("test fixture only has %d unique colors, want enough to exercise quantization", got)
+	}
+
+	pi, err := Quantize(im, 16, false)
+	if err != nil {
+		t.Fatalf("Quantize: %v", err)
+	}
+	if len(pi.Palette) != 16 {
+		t.Fatalf("len(Palette) = %d, want 16", len(pi.Palette))
+	}
+	if got := countUniqueColors(pi); got > 16 {
+		t.Fatalf("quantized image has %d unique colors, want at most 16", got)
+	}
+}
+
+func TestQuantizeDither(t *testing.T) {
+	im := randomNRGBA(32, 32, 2)
+	pi, err := Quantize(im, 16, true)
+	if err != nil {
+		t.Fatalf("Quantize: %v", err)
+	}
+	if len(pi.Palette) != 16 {
+		t.Fatalf("len(Palette) = %d, want 16", len(pi.Palette))
+	}
+	if got := countUniqueColors(pi); got > 16 {
+		t.Fatalf("dithered image has %d unique colors, want at most 16", got)
+	}
+}
+
+func TestQuantizeInvalidSize(t *testing.T) {
+	im := randomNRGBA(4, 4, 3)
+	for _, n := range []int{0, 1, 257, -5} {
+		if _, err := Quantize(im, n, false); err == nil {
+			t.Errorf("Quantize(n=%d): want error, got nil", n)
+		}
+	}
+}
+
+func TestQuantizeSolidColorCollapsesToOneBox(t *testing.T) {
+	im := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	solid := color.NRGBA{R: 100, G: 150, B: 200, A: 255}
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			im.SetNRGBA(x, y, solid)
+		}
+	}
+
+	// Median cut has nothing left to split once every sample is
+	// identical; requesting 16 colors from a 1-color image should not
+	// panic and should still reproduce the original color.
+	pi, err := Quantize(im, 16, false)
+	if err != nil {
+		t.Fatalf("Quantize: %v", err)
+	}
+	r, g, b, _ := pi.At(0, 0).RGBA()
+	if byte(r>>8) != solid.R || byte(g>>8) != solid.G || byte(b>>8) != solid.B {
+		t.Fatalf("solid-color quantization produced %v, want %v", pi.At(0, 0), solid)
+	}
+}
+
+func TestKDTreeNearest(t *testing.T) {
+	pal := color.Palette{
+		color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+		color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		color.NRGBA{R: 255, G: 0, B: 0, A: 255},
+	}
+	tree := newKDTree(pal)
+
+	cases := []struct {
+		c    rgb
+		want byte
+	}{
+		{rgb{10, 10, 10}, 0},
+		{rgb{250, 250, 250}, 1},
+		{rgb{240, 5, 5}, 2},
+	}
+	for _, c := range cases {
+		if got := tree.nearest(c.c); got != c.want {
+			t.Errorf("nearest(%v) = %d, want %d", c.c, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package imageunpacker
+
+import (
+	"math"
+	"sort"
+)
+
+// A ToneMap compresses an HDR channel value (which may exceed 1.0) down
+// into the displayable [0, 1] range.
+type ToneMap interface {
+	Map(x float64) float64
+}
+
+// ReinhardToneMap implements the global Reinhard operator: x / (1 + x).
+type ReinhardToneMap struct{}
+
+func (ReinhardToneMap) Map(x float64) float64 {
+	return x / (1 + x)
+}
+
+// ReinhardExtendedToneMap implements the "extended" Reinhard operator,
+// which leaves values at or above White mapped to 1.0 instead of
+// asymptotically approaching it.
+type ReinhardExtendedToneMap struct {
+	// White is the luminance that maps to 1.0. A White of 0 is equivalent
+	// to ReinhardToneMap (no white point, i.e. White == +Inf).
+	White float64
+}
+
+func (t ReinhardExtendedToneMap) Map(x float64) float64 {
+	if t.White == 0 {
+		return x / (1 + x)
+	}
+	return x * (1 + x/(t.White*t.White)) / (1 + x)
+}
+
+// ACESToneMap implements Krzysztof Narkowicz's fit to the ACES filmic
+// tone curve.
+type ACESToneMap struct{}
+
+func (ACESToneMap) Map(x float64) float64 {
+	const a, b, c, d, e = 2.51, 0.03, 2.43, 0.59, 0.14
+	y := (x * (a*x + b)) / (x*(c*x+d) + e)
+	return clamp(y, 0, 1)
+}
+
+func clamp(x, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, x))
+}
+
+// srgbOETF applies the sRGB opto-electronic transfer function, converting
+// a linear value in [0, 1] to a gamma-encoded one.
+func srgbOETF(x float64) float64 {
+	if x <= 0.0031308 {
+		return 12.92 * x
+	}
+	return 1.055*math.Pow(x, 1/2.4) - 0.055
+}
+
+// luminance is the Rec. 709 relative luminance of a linear RGB triple.
+func luminance(r, g, b float64) float64 {
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+// percentile returns the value at the given percentile (0-100) of a sorted
+// copy of xs. An empty xs returns 0.
+func percentile(xs []float64, p float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), xs...)
+	sort.Float64s(sorted)
+	i := int(p / 100 * float64(len(sorted)-1))
+	return sorted[i]
+}
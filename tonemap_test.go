@@ -0,0 +1,67 @@
+package imageunpacker
+
+import (
+	"math"
+	"testing"
+)
+
+func approxEqual(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	const epsilon = 1e-6
+	if math.Abs(got-want) > epsilon {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestReinhardToneMap(t *testing.T) {
+	tm := ReinhardToneMap{}
+	for _, x := range []float64{0.0, 0.5, 1.0, 2.0, 10.0} {
+		want := x / (1 + x)
+		approxEqual(t, "Reinhard.Map", tm.Map(x), want)
+	}
+}
+
+func TestReinhardExtendedToneMap(t *testing.T) {
+	tm := ReinhardExtendedToneMap{White: 2.0}
+	for _, x := range []float64{0.0, 0.5, 1.0, 2.0} {
+		want := x * (1 + x/4.0) / (1 + x)
+		approxEqual(t, "ReinhardExtended.Map", tm.Map(x), want)
+	}
+
+	// A zero White behaves like plain Reinhard.
+	zeroWhite := ReinhardExtendedToneMap{}
+	approxEqual(t, "ReinhardExtended{White:0}.Map(0.5)", zeroWhite.Map(0.5), ReinhardToneMap{}.Map(0.5))
+}
+
+func TestACESToneMap(t *testing.T) {
+	tm := ACESToneMap{}
+	cases := []struct{ x, want float64 }{
+		{0.5, (0.5 * (2.51*0.5 + 0.03)) / (0.5*(2.43*0.5+0.59) + 0.14)},
+		{2.0, (2.0 * (2.51*2.0 + 0.03)) / (2.0*(2.43*2.0+0.59) + 0.14)},
+	}
+	for _, c := range cases {
+		approxEqual(t, "ACES.Map", tm.Map(c.x), c.want)
+	}
+
+	// Always clamped to [0, 1], even for very bright input.
+	if y := tm.Map(1000); y < 0 || y > 1 {
+		t.Errorf("ACES.Map(1000) = %v, want value in [0, 1]", y)
+	}
+}
+
+func TestSRGBOETF(t *testing.T) {
+	// Below the linear-segment threshold.
+	approxEqual(t, "srgbOETF(0.002)", srgbOETF(0.002), 12.92*0.002)
+
+	// Above it, the power-law segment.
+	x := 0.5
+	want := 1.055*math.Pow(x, 1/2.4) - 0.055
+	approxEqual(t, "srgbOETF(0.5)", srgbOETF(x), want)
+}
+
+func TestLuminance(t *testing.T) {
+	approxEqual(t, "luminance(1,0,0)", luminance(1, 0, 0), 0.2126)
+	approxEqual(t, "luminance(0,1,0)", luminance(0, 1, 0), 0.7152)
+	approxEqual(t, "luminance(0,0,1)", luminance(0, 0, 1), 0.0722)
+	approxEqual(t, "luminance(1,1,1)", luminance(1, 1, 1), 1.0)
+}
@@ -0,0 +1,11 @@
+package imageunpacker
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// float32From reads a little-endian float32 from the front of b.
+func float32From(b []byte) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(b))
+}